@@ -1,6 +1,8 @@
 package main
 
 import (
+	"os"
+
 	"go.viam.com/rdk/components/sensor"
 	"go.viam.com/rdk/module"
 	"go.viam.com/rdk/resource"
@@ -8,6 +10,12 @@ import (
 )
 
 func main() {
+	// Opt-in Prometheus metrics: set WEATHERSENSOR_METRICS_ADDR (e.g. ":9101")
+	// to expose /metrics for upstream API call and cache observability.
+	if addr := os.Getenv("WEATHERSENSOR_METRICS_ADDR"); addr != "" {
+		models.StartMetricsServer(addr)
+	}
+
 	// ModularMain can take multiple APIModel arguments, if your module implements multiple models.
 	module.ModularMain(resource.APIModel{sensor.API, models.Weathersensor})
 }
@@ -0,0 +1,126 @@
+package models
+
+import (
+	"context"
+	"time"
+)
+
+// weatherCache holds the most recent successful fetch from the configured
+// Provider. Readings serves straight from here instead of making a
+// synchronous HTTP call on every poll.
+type weatherCache struct {
+	obs       Observation
+	astro     Astronomy
+	fetchedAt time.Time
+}
+
+// pollLoop refreshes the cache until s.cancelCtx is canceled. It fetches
+// once immediately so the cache isn't empty for the entire first interval,
+// and re-reads the poll interval on every iteration so a Reconfigure that
+// changes poll_interval_sec/cache_ttl_sec takes effect without restarting
+// the module.
+func (s *weathersensorWeathersensor) pollLoop() {
+	defer s.wg.Done()
+	s.pollOnce()
+
+	timer := time.NewTimer(s.currentPollInterval())
+	defer timer.Stop()
+	for {
+		select {
+		case <-s.cancelCtx.Done():
+			return
+		case <-timer.C:
+			s.pollOnce()
+			timer.Reset(s.currentPollInterval())
+		}
+	}
+}
+
+func (s *weathersensorWeathersensor) pollOnce() {
+	provider, location, _, _ := s.snapshotConfig()
+	if err := s.refreshCache(s.cancelCtx, provider, location); err != nil {
+		s.logger.Errorf("Error refreshing cached weather: %v\n", err)
+	}
+}
+
+// fetch fetches current weather and astronomy from provider. Providers that
+// implement CombinedProvider derive both from a single upstream response;
+// others get two independent calls fired concurrently, since they hit
+// separate endpoints.
+func fetch(ctx context.Context, provider Provider, location string) (Observation, Astronomy, error) {
+	loc := Location{Query: location}
+
+	if combined, ok := provider.(CombinedProvider); ok {
+		return combined.CurrentAndAstronomy(ctx, loc, time.Now())
+	}
+
+	type obsResult struct {
+		obs Observation
+		err error
+	}
+	type astroResult struct {
+		astro Astronomy
+		err   error
+	}
+	obsCh := make(chan obsResult, 1)
+	astroCh := make(chan astroResult, 1)
+
+	go func() {
+		obs, err := provider.CurrentWeather(ctx, loc)
+		obsCh <- obsResult{obs, err}
+	}()
+	go func() {
+		astro, err := provider.Astronomy(ctx, loc, time.Now())
+		astroCh <- astroResult{astro, err}
+	}()
+
+	obsRes, astroRes := <-obsCh, <-astroCh
+	if obsRes.err != nil {
+		return Observation{}, Astronomy{}, obsRes.err
+	}
+	if astroRes.err != nil {
+		return Observation{}, Astronomy{}, astroRes.err
+	}
+	return obsRes.obs, astroRes.astro, nil
+}
+
+// refreshCache fetches current weather and astronomy and stores them on
+// success. A failed refresh leaves the previous cache entry in place so
+// Readings keeps serving the last known-good snapshot (marked stale) rather
+// than erroring.
+func (s *weathersensorWeathersensor) refreshCache(ctx context.Context, provider Provider, location string) error {
+	obs, astro, err := fetch(ctx, provider, location)
+	if err != nil {
+		return err
+	}
+
+	s.cacheMu.Lock()
+	s.cache = &weatherCache{obs: obs, astro: astro, fetchedAt: time.Now()}
+	s.cacheMu.Unlock()
+	return nil
+}
+
+// snapshot returns the current cache entry and how stale it is relative to
+// cacheTTL. If nothing has been cached yet, it blocks on a synchronous fetch
+// so the very first Readings call doesn't need to wait a full poll interval.
+func (s *weathersensorWeathersensor) snapshot(ctx context.Context, provider Provider, location string, cacheTTL time.Duration) (weatherCache, bool, error) {
+	s.cacheMu.RLock()
+	cache := s.cache
+	s.cacheMu.RUnlock()
+
+	if cache != nil {
+		cacheHitsTotal.Inc()
+	} else {
+		obs, astro, err := fetch(ctx, provider, location)
+		if err != nil {
+			return weatherCache{}, false, err
+		}
+		cache = &weatherCache{obs: obs, astro: astro, fetchedAt: time.Now()}
+		s.cacheMu.Lock()
+		s.cache = cache
+		s.cacheMu.Unlock()
+	}
+
+	stale := time.Since(cache.fetchedAt) > cacheTTL
+	return *cache, stale, nil
+}
@@ -0,0 +1,49 @@
+package models
+
+import "github.com/pkg/errors"
+
+// Units selects the unit system used to populate Readings output. Providers
+// normalize their responses into metric Observation/Astronomy values; these
+// helpers convert to imperial or SI as the config requests.
+type Units string
+
+const (
+	UnitsImperial Units = "imperial"
+	UnitsMetric   Units = "metric"
+	UnitsSI       Units = "si"
+)
+
+func parseUnits(raw string) (Units, error) {
+	switch Units(raw) {
+	case "":
+		return UnitsImperial, nil
+	case UnitsImperial, UnitsMetric, UnitsSI:
+		return Units(raw), nil
+	default:
+		return "", errors.Errorf(`invalid "units" value %q, expected "imperial", "metric", or "si"`, raw)
+	}
+}
+
+func celsiusToFahrenheit(c float64) float64 {
+	return c*9/5 + 32
+}
+
+func kphToMph(kph float64) float64 {
+	return kph * 0.621371
+}
+
+func kphToMS(kph float64) float64 {
+	return kph * 1000 / 3600
+}
+
+func mbToInHg(mb float64) float64 {
+	return mb * 0.0295301
+}
+
+func mmToInches(mm float64) float64 {
+	return mm / 25.4
+}
+
+func kmToMiles(km float64) float64 {
+	return km * 0.621371
+}
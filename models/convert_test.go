@@ -0,0 +1,87 @@
+package models
+
+import "testing"
+
+func TestParseUnits(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    Units
+		wantErr bool
+	}{
+		{raw: "", want: UnitsImperial},
+		{raw: "imperial", want: UnitsImperial},
+		{raw: "metric", want: UnitsMetric},
+		{raw: "si", want: UnitsSI},
+		{raw: "kelvin", wantErr: true},
+	}
+	for _, tc := range tests {
+		got, err := parseUnits(tc.raw)
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("parseUnits(%q): expected error, got nil", tc.raw)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseUnits(%q): unexpected error: %v", tc.raw, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("parseUnits(%q) = %q, want %q", tc.raw, got, tc.want)
+		}
+	}
+}
+
+func TestCelsiusToFahrenheit(t *testing.T) {
+	tests := []struct {
+		c, want float64
+	}{
+		{c: 0, want: 32},
+		{c: 100, want: 212},
+		{c: -40, want: -40},
+	}
+	for _, tc := range tests {
+		if got := celsiusToFahrenheit(tc.c); got != tc.want {
+			t.Errorf("celsiusToFahrenheit(%v) = %v, want %v", tc.c, got, tc.want)
+		}
+	}
+}
+
+func TestKphToMph(t *testing.T) {
+	got := kphToMph(100)
+	want := 62.1371
+	if diff := got - want; diff > 1e-9 || diff < -1e-9 {
+		t.Errorf("kphToMph(100) = %v, want %v", got, want)
+	}
+}
+
+func TestKphToMS(t *testing.T) {
+	got := kphToMS(36)
+	want := 10.0
+	if diff := got - want; diff > 1e-9 || diff < -1e-9 {
+		t.Errorf("kphToMS(36) = %v, want %v", got, want)
+	}
+}
+
+func TestMbToInHg(t *testing.T) {
+	got := mbToInHg(1013.25)
+	want := 29.9213
+	if diff := got - want; diff > 1e-3 || diff < -1e-3 {
+		t.Errorf("mbToInHg(1013.25) = %v, want ~%v", got, want)
+	}
+}
+
+func TestMmToInches(t *testing.T) {
+	got := mmToInches(25.4)
+	if got != 1 {
+		t.Errorf("mmToInches(25.4) = %v, want 1", got)
+	}
+}
+
+func TestKmToMiles(t *testing.T) {
+	got := kmToMiles(1)
+	want := 0.621371
+	if diff := got - want; diff > 1e-9 || diff < -1e-9 {
+		t.Errorf("kmToMiles(1) = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,75 @@
+package models
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	apiRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "weathersensor_api_requests_total",
+		Help: "Total upstream weather API requests, by endpoint and response code.",
+	}, []string{"endpoint", "code"})
+
+	apiDurationSeconds = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "weathersensor_api_duration_seconds",
+		Help: "Latency of upstream weather API requests, by endpoint.",
+	}, []string{"endpoint"})
+
+	cacheHitsTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "weathersensor_cache_hits_total",
+		Help: "Total Readings calls served from the in-memory cache instead of a live fetch.",
+	})
+
+	outsideTempF = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "weathersensor_outside_temp_fahrenheit",
+		Help: "Last observed outside temperature, in Fahrenheit, by resource.",
+	}, []string{"resource"})
+
+	insideTempF = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "weathersensor_inside_temp_fahrenheit",
+		Help: "Last observed inside temperature, in Fahrenheit, by resource.",
+	}, []string{"resource"})
+
+	cloudCoverPctGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "weathersensor_cloud_cover_pct",
+		Help: "Last observed cloud cover percentage, by resource.",
+	}, []string{"resource"})
+)
+
+// StartMetricsServer starts a background HTTP server exposing Prometheus
+// metrics at /metrics on addr. Callers that want a clean shutdown can use
+// the returned *http.Server; main.go otherwise leaves it running for the
+// lifetime of the process.
+func StartMetricsServer(addr string) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	srv := &http.Server{Addr: addr, Handler: mux}
+	go func() {
+		_ = srv.ListenAndServe()
+	}()
+	return srv
+}
+
+// observeAPICall records an upstream API call's outcome for the
+// weathersensor_api_requests_total and weathersensor_api_duration_seconds
+// metrics.
+func observeAPICall(endpoint string, code int, duration time.Duration) {
+	apiRequestsTotal.WithLabelValues(endpoint, strconv.Itoa(code)).Inc()
+	apiDurationSeconds.WithLabelValues(endpoint).Observe(duration.Seconds())
+}
+
+// observeReading records the last observed outside/inside temperature and
+// cloud cover for a given resource, keyed by name so that multiple
+// weathersensor components on the same robot don't overwrite each other's
+// gauges.
+func observeReading(resource string, outsideF, insideF, cloudCoverPct float64) {
+	outsideTempF.WithLabelValues(resource).Set(outsideF)
+	insideTempF.WithLabelValues(resource).Set(insideF)
+	cloudCoverPctGauge.WithLabelValues(resource).Set(cloudCoverPct)
+}
@@ -2,10 +2,9 @@ package models
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
@@ -16,12 +15,17 @@ import (
 	"go.viam.com/utils/rpc"
 )
 
+// ProviderWeatherAPI and ProviderOpenWeatherMap are the supported values for
+// Config.Provider. ProviderWeatherAPI is the default, matching this module's
+// original (and only) backend.
 const (
-	currentWeatherURL = "https://api.weatherapi.com/v1/current.json"
-	astronomyURL      = "https://api.weatherapi.com/v1/astronomy.json"
+	ProviderWeatherAPI     = "weatherapi"
+	ProviderOpenWeatherMap = "openweathermap"
 )
 
-// api ref: https://app.swaggerhub.com/apis-docs/WeatherAPI.com/WeatherAPI/1.0.2#/APIs/realtime-weather
+// defaultPollInterval is used when neither PollIntervalSec nor CacheTTLSec
+// is set, keeping well within weatherapi.com's free-tier rate limits.
+const defaultPollInterval = 5 * time.Minute
 
 var (
 	Weathersensor    = resource.NewModel("vijayvuyyuru", "weathersensor", "weathersensor")
@@ -38,9 +42,40 @@ func init() {
 
 type Config struct {
 	TemperatureSensor string `json:"temp-sensor"`
-	Zipcode           int    `json:"zipcode"`
 	APIKey            string `json:"apikey"`
 
+	// APIKeyFile is a path to a file containing the WeatherAPI key, trimmed
+	// of trailing whitespace. Used when APIKey is empty. APIKey may also be
+	// set to "$ENV_VAR" to resolve the key from that environment variable
+	// instead.
+	APIKeyFile string `json:"apikey_file,omitempty"`
+
+	// Location accepts anything WeatherAPI's `q=` parameter does: a US zip
+	// code, "lat,lon", a city name, or an IATA airport code.
+	Location string `json:"location,omitempty"`
+
+	// Zipcode is deprecated in favor of Location, which accepts zip codes
+	// plus everything else WeatherAPI's `q=` parameter understands. Kept for
+	// backwards compatibility with existing configs.
+	Zipcode int `json:"zipcode,omitempty"`
+
+	// Units selects the unit system used to populate Readings: "imperial"
+	// (default), "metric", or "si".
+	Units string `json:"units,omitempty"`
+
+	// Provider selects the upstream weather backend: "weatherapi" (default)
+	// or "openweathermap". APIKey is required for "weatherapi"; AppID is
+	// required for "openweathermap".
+	Provider string `json:"provider,omitempty"`
+	AppID    string `json:"appid,omitempty"`
+
+	// PollIntervalSec controls how often the background cache refresh hits
+	// the provider, and CacheTTLSec controls how old a cached reading can be
+	// before Readings reports it as stale. Either may be set; if only one is
+	// set, it's used for both. Defaults to 5 minutes.
+	PollIntervalSec int `json:"poll_interval_sec,omitempty"`
+	CacheTTLSec     int `json:"cache_ttl_sec,omitempty"`
+
 	/*
 		Put config attributes here. There should be public/exported fields
 		with a `json` parameter at the end of each attribute.
@@ -61,6 +96,19 @@ type Config struct {
 	// resource.TriviallyValidateConfig
 }
 
+// location returns the resolved WeatherAPI `q=` value, preferring the new
+// Location field but falling back to the legacy Zipcode for backwards
+// compatibility.
+func (cfg *Config) location() string {
+	if cfg.Location != "" {
+		return cfg.Location
+	}
+	if cfg.Zipcode != 0 {
+		return strconv.Itoa(cfg.Zipcode)
+	}
+	return ""
+}
+
 // Validate ensures all parts of the config are valid and important fields exist.
 // Returns implicit dependencies based on the config.
 // The path is the JSON path in your robot's config (not the `Config` struct) to the
@@ -69,15 +117,71 @@ func (cfg *Config) Validate(path string) ([]string, []string, error) {
 	if cfg.TemperatureSensor == "" {
 		return nil, nil, fmt.Errorf(`expected "temp-sensor" attribute for weather module`)
 	}
-	if cfg.APIKey == "" {
-		return nil, nil, fmt.Errorf(`expected "apikey" attribute for weather module`)
+	if cfg.location() == "" {
+		return nil, nil, fmt.Errorf(`expected "location" (or legacy "zipcode") attribute for weather module`)
 	}
-	if cfg.Zipcode == 0 {
-		return nil, nil, fmt.Errorf(`expected "zipcode" attribute for weather module`)
+	if _, err := parseUnits(cfg.Units); err != nil {
+		return nil, nil, err
+	}
+	switch cfg.provider() {
+	case ProviderOpenWeatherMap:
+		if cfg.AppID == "" {
+			return nil, nil, fmt.Errorf(`expected "appid" attribute for weather module using the %q provider`, ProviderOpenWeatherMap)
+		}
+	case ProviderWeatherAPI:
+		apiKey, err := cfg.resolveAPIKey()
+		if err != nil {
+			return nil, nil, err
+		}
+		if apiKey == "" {
+			return nil, nil, fmt.Errorf(`expected "apikey" (or "apikey_file") attribute for weather module`)
+		}
+	default:
+		return nil, nil, fmt.Errorf(`invalid "provider" value %q, expected %q or %q`, cfg.Provider, ProviderWeatherAPI, ProviderOpenWeatherMap)
 	}
 	return []string{cfg.TemperatureSensor}, nil, nil
 }
 
+// resolveAPIKey resolves the WeatherAPI key from APIKey, an "$ENV_VAR"
+// reference, or APIKeyFile, in that order.
+func (cfg *Config) resolveAPIKey() (string, error) {
+	return resolveSecret(cfg.APIKey, cfg.APIKeyFile)
+}
+
+// provider returns the configured provider name, defaulting to
+// ProviderWeatherAPI when unset.
+func (cfg *Config) provider() string {
+	if cfg.Provider == "" {
+		return ProviderWeatherAPI
+	}
+	return cfg.Provider
+}
+
+// pollInterval returns how often the background cache refresh should run,
+// falling back from PollIntervalSec to CacheTTLSec to defaultPollInterval.
+func (cfg *Config) pollInterval() time.Duration {
+	if cfg.PollIntervalSec > 0 {
+		return time.Duration(cfg.PollIntervalSec) * time.Second
+	}
+	if cfg.CacheTTLSec > 0 {
+		return time.Duration(cfg.CacheTTLSec) * time.Second
+	}
+	return defaultPollInterval
+}
+
+// cacheTTL returns how old a cached reading can be before it's reported as
+// stale, falling back from CacheTTLSec to PollIntervalSec to
+// defaultPollInterval.
+func (cfg *Config) cacheTTL() time.Duration {
+	if cfg.CacheTTLSec > 0 {
+		return time.Duration(cfg.CacheTTLSec) * time.Second
+	}
+	if cfg.PollIntervalSec > 0 {
+		return time.Duration(cfg.PollIntervalSec) * time.Second
+	}
+	return defaultPollInterval
+}
+
 type weathersensorWeathersensor struct {
 	name resource.Name
 
@@ -88,12 +192,21 @@ type weathersensorWeathersensor struct {
 	cancelFunc func()
 
 	temperatureSensor sensor.Sensor
-	apiKey            string
-	zipcode           int
 
-	// Uncomment this if the model does not have any goroutines that
-	// need to be shut down while closing.
-	resource.TriviallyCloseable
+	// cfgMu guards the fields below, which Reconfigure can update while
+	// pollLoop is concurrently reading them in the background.
+	cfgMu        sync.RWMutex
+	provider     Provider
+	location     string
+	units        Units
+	pollInterval time.Duration
+	cacheTTL     time.Duration
+
+	startOnce sync.Once
+	wg        sync.WaitGroup
+
+	cacheMu sync.RWMutex
+	cache   *weatherCache
 }
 
 func newWeathersensorWeathersensor(ctx context.Context, deps resource.Dependencies, rawConf resource.Config, logger logging.Logger) (sensor.Sensor, error) {
@@ -114,6 +227,10 @@ func newWeathersensorWeathersensor(ctx context.Context, deps resource.Dependenci
 	if err := s.Reconfigure(ctx, deps, rawConf); err != nil {
 		return nil, err
 	}
+	s.startOnce.Do(func() {
+		s.wg.Add(1)
+		go s.pollLoop()
+	})
 	return s, nil
 }
 
@@ -130,111 +247,198 @@ func (s *weathersensorWeathersensor) Reconfigure(ctx context.Context, deps resou
 	if err != nil {
 		return errors.Wrapf(err, "unable to get temperature sensor %v for weather sensor", sensorConfig.TemperatureSensor)
 	}
-	s.apiKey = sensorConfig.APIKey
-	s.zipcode = sensorConfig.Zipcode
+	units, err := parseUnits(sensorConfig.Units)
+	if err != nil {
+		return err
+	}
+	provider, err := newProvider(sensorConfig, s.logger)
+	if err != nil {
+		return err
+	}
+
+	s.cfgMu.Lock()
+	s.location = sensorConfig.location()
+	s.units = units
+	s.provider = provider
+	s.pollInterval = sensorConfig.pollInterval()
+	s.cacheTTL = sensorConfig.cacheTTL()
+	s.cfgMu.Unlock()
 	return nil
 }
 
+// snapshotConfig returns the provider, location, units, and cacheTTL
+// currently in effect, guarding against a concurrent Reconfigure.
+func (s *weathersensorWeathersensor) snapshotConfig() (Provider, string, Units, time.Duration) {
+	s.cfgMu.RLock()
+	defer s.cfgMu.RUnlock()
+	return s.provider, s.location, s.units, s.cacheTTL
+}
+
+// currentPollInterval returns the poll interval currently in effect,
+// guarding against a concurrent Reconfigure.
+func (s *weathersensorWeathersensor) currentPollInterval() time.Duration {
+	s.cfgMu.RLock()
+	defer s.cfgMu.RUnlock()
+	return s.pollInterval
+}
+
+// newProvider builds the Provider selected by cfg.Provider.
+func newProvider(cfg *Config, logger logging.Logger) (Provider, error) {
+	switch cfg.provider() {
+	case ProviderOpenWeatherMap:
+		return newOpenWeatherMapProvider(cfg.AppID, logger), nil
+	case ProviderWeatherAPI:
+		apiKey, err := cfg.resolveAPIKey()
+		if err != nil {
+			return nil, err
+		}
+		return newWeatherAPIProvider(apiKey, logger), nil
+	default:
+		return nil, fmt.Errorf(`invalid "provider" value %q, expected %q or %q`, cfg.Provider, ProviderWeatherAPI, ProviderOpenWeatherMap)
+	}
+}
+
 func (s *weathersensorWeathersensor) NewClientFromConn(ctx context.Context, conn rpc.ClientConn, remoteName string, name resource.Name, logger logging.Logger) (sensor.Sensor, error) {
 	panic("not implemented")
 }
 
 func (s *weathersensorWeathersensor) Readings(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
 	output := map[string]any{}
-	response, err := s.getCurrentWeather()
+	provider, location, units, cacheTTL := s.snapshotConfig()
+	cache, stale, err := s.snapshot(ctx, provider, location, cacheTTL)
 	if err != nil {
 		return nil, err
 	}
-	astronomyResponse, err := s.getCurrentAstronomy()
-	if err != nil {
-		return nil, err
+	obs, astro := cache.obs, cache.astro
+	output["last_updated"] = cache.fetchedAt.Format(time.RFC3339)
+	output["stale"] = stale
+
+	output["condition"] = obs.Condition
+	output["code"] = obs.ConditionCode
+	output["cloud_cover_pct"] = obs.CloudCoverPct
+	output["humidity_pct"] = obs.HumidityPct
+	output["uv_index"] = obs.UVIndex
+	if obs.AirQuality != nil {
+		output["air_quality"] = obs.AirQuality
+	}
+	output["wind_degree"] = obs.WindDegree
+	output["wind_dir"] = obs.WindDir
+
+	switch units {
+	case UnitsImperial:
+		output["outside_f"] = celsiusToFahrenheit(obs.TempC)
+		output["feelslike_f"] = celsiusToFahrenheit(obs.FeelslikeC)
+		output["dewpoint_f"] = celsiusToFahrenheit(obs.DewpointC)
+		output["wind_mph"] = kphToMph(obs.WindKph)
+		output["gust_mph"] = kphToMph(obs.GustKph)
+		output["pressure_in"] = mbToInHg(obs.PressureMb)
+		output["precipitation_inches"] = mmToInches(obs.PrecipMm)
+		output["visibility_miles"] = kmToMiles(obs.VisKm)
+	case UnitsMetric:
+		output["outside_c"] = obs.TempC
+		output["feelslike_c"] = obs.FeelslikeC
+		output["dewpoint_c"] = obs.DewpointC
+		output["wind_kph"] = obs.WindKph
+		output["gust_kph"] = obs.GustKph
+		output["pressure_mb"] = obs.PressureMb
+		output["precipitation_mm"] = obs.PrecipMm
+		output["visibility_km"] = obs.VisKm
+	case UnitsSI:
+		output["outside_c"] = obs.TempC
+		output["feelslike_c"] = obs.FeelslikeC
+		output["dewpoint_c"] = obs.DewpointC
+		output["wind_ms"] = kphToMS(obs.WindKph)
+		output["gust_ms"] = kphToMS(obs.GustKph)
+		output["pressure_mb"] = obs.PressureMb
+		output["precipitation_mm"] = obs.PrecipMm
+		output["visibility_km"] = obs.VisKm
 	}
-	currentWeather := response["current"].(map[string]any)
-	output["outside_f"] = currentWeather["temp_f"]
-	output["condition"] = currentWeather["condition"].(map[string]any)["text"]
-	output["code"] = currentWeather["condition"].(map[string]any)["code"]
-	output["cloud_cover_pct"] = currentWeather["cloud"].(float64)
-	output["precipitation_inches"] = currentWeather["precip_in"]
 
-	astronomy := astronomyResponse["astronomy"].(map[string]any)["astro"].(map[string]any)
-	output["is_day"] = astronomy["is_sun_up"]
+	output["is_day"] = astro.IsSunUp
+	output["sunrise"] = astro.Sunrise
+	output["sunset"] = astro.Sunset
+	output["moonrise"] = astro.Moonrise
+	output["moonset"] = astro.Moonset
+	output["moon_phase"] = astro.MoonPhase
+	output["moon_illumination"] = astro.MoonIllumination
 
 	readings, err := s.temperatureSensor.Readings(ctx, nil)
 	if err != nil {
 		return nil, errors.Wrapf(err, "error getting reading from temp sensor")
 	}
 	insideTempC := readings["degrees_celsius"].(float64)
-	output["inside_f"] = insideTempC*9/5 + 32
+	switch units {
+	case UnitsMetric, UnitsSI:
+		output["inside_c"] = insideTempC
+	default:
+		output["inside_f"] = celsiusToFahrenheit(insideTempC)
+	}
+
+	observeReading(s.name.String(), celsiusToFahrenheit(obs.TempC), celsiusToFahrenheit(insideTempC), obs.CloudCoverPct)
 	return output, nil
 }
 
+// DoCommand exposes ad-hoc weather queries that don't belong in the small,
+// periodic Readings path:
+//
+//	{"command": "forecast", "days": 3}
+//	{"command": "history", "date": "YYYY-MM-DD"}
+//	{"command": "alerts"}
+//	{"command": "search", "q": "..."}
+//	{"command": "refresh"}
 func (s *weathersensorWeathersensor) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
-	panic("not implemented")
-}
-
-func (s *weathersensorWeathersensor) Close(context.Context) error {
-	// Put close code here
-	s.cancelFunc()
-	return nil
-}
-
-func (s *weathersensorWeathersensor) getCurrentAstronomy() (map[string]any, error) {
-	url := fmt.Sprintf("%s?q=%d&dt=%s&key=%s", astronomyURL, s.zipcode, time.Now().Format("2006-01-02"), s.apiKey)
-	return s.getWeatherBodyOrCode(url)
-}
-
-func (s *weathersensorWeathersensor) getCurrentWeather() (map[string]any, error) {
-	url := fmt.Sprintf("%s?q=%d&key=%s", currentWeatherURL, s.zipcode, s.apiKey)
-	return s.getWeatherBodyOrCode(url)
-}
-
-func (s *weathersensorWeathersensor) getWeatherBodyOrCode(url string) (map[string]any, error) {
-	client := &http.Client{
-		Timeout: 10 * time.Second,
+	commandRaw, ok := cmd["command"]
+	if !ok {
+		return nil, errors.New(`expected "command" attribute in DoCommand map`)
 	}
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		s.logger.Errorf("Error creating request: %v\n", err)
-		return nil, err
+	command, ok := commandRaw.(string)
+	if !ok {
+		return nil, errors.New(`expected "command" attribute to be a string`)
 	}
-	req.Header.Add("Accept", "application/json")
 
-	// Send the request
-	resp, err := client.Do(req)
-	if err != nil {
-		s.logger.Errorf("Error making request: %v\n", err)
-		return nil, err
-	}
-	defer resp.Body.Close()
+	provider, location, _, _ := s.snapshotConfig()
 
-	// Read the response body
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		s.logger.Errorf("Error reading response body: %v\n", err)
-		return nil, err
+	if command == "refresh" {
+		if err := s.refreshCache(ctx, provider, location); err != nil {
+			return nil, errors.Wrapf(err, "error refreshing cached weather")
+		}
+		return map[string]interface{}{"refreshed": true}, nil
 	}
-	var responseJSON map[string]interface{}
-	if err := json.Unmarshal(body, &responseJSON); err != nil {
-		s.logger.Errorf("Error unmarshaling to map: %v\n", err)
-		return nil, err
+
+	ext, ok := provider.(ExtendedProvider)
+	if !ok {
+		return nil, errors.Errorf("configured provider does not support the %q command", command)
 	}
+	loc := Location{Query: location}
 
-	// Check status code
-	if resp.StatusCode != http.StatusOK {
-		s.logger.Errorf("Unexpected status code: %d\n", resp.StatusCode)
-		code, ok := responseJSON["code"]
+	switch command {
+	case "forecast":
+		days := 3
+		if d, ok := cmd["days"].(float64); ok {
+			days = int(d)
+		}
+		return ext.Forecast(ctx, loc, days)
+	case "history":
+		date, ok := cmd["date"].(string)
 		if !ok {
-			return nil, errors.Errorf("request failed with code %d, and no code in response body", resp.StatusCode)
+			return nil, errors.New(`expected "date" attribute for the "history" command`)
 		}
-		message, messageOK := responseJSON["message"]
-		if !messageOK {
-			return nil, errors.Errorf("request failed with code %d, and no message in response body", resp.StatusCode)
+		return ext.History(ctx, loc, date)
+	case "alerts":
+		return ext.Alerts(ctx, loc)
+	case "search":
+		query, ok := cmd["q"].(string)
+		if !ok {
+			return nil, errors.New(`expected "q" attribute for the "search" command`)
 		}
-		return responseJSON, errors.Errorf("error fetching weather info, code: %d, message: %s", code, message)
+		return ext.Search(ctx, query)
+	default:
+		return nil, errors.Errorf("unknown command %q", command)
 	}
-	return responseJSON, nil
 }
 
-func (s *weathersensorWeathersensor) getCurrentWeatherURL() string {
-	return fmt.Sprintf("%s?q=%d&key=%s", currentWeatherURL, s.zipcode, s.apiKey)
+func (s *weathersensorWeathersensor) Close(context.Context) error {
+	s.cancelFunc()
+	s.wg.Wait()
+	return nil
 }
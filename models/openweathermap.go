@@ -0,0 +1,211 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/logging"
+)
+
+// api ref: https://openweathermap.org/current
+
+const openWeatherMapCurrentURL = "https://api.openweathermap.org/data/2.5/weather"
+
+// openWeatherMapProvider implements Provider against OpenWeatherMap's
+// current-weather endpoint. It has no moon data, so Astronomy only
+// populates sunrise/sunset/is-sun-up.
+type openWeatherMapProvider struct {
+	appID  string
+	logger logging.Logger
+}
+
+func newOpenWeatherMapProvider(appID string, logger logging.Logger) *openWeatherMapProvider {
+	return &openWeatherMapProvider{appID: appID, logger: logger}
+}
+
+func (p *openWeatherMapProvider) CurrentWeather(ctx context.Context, loc Location) (Observation, error) {
+	body, err := p.get(ctx, loc, "current")
+	if err != nil {
+		return Observation{}, err
+	}
+	return observationFromBody(body), nil
+}
+
+func (p *openWeatherMapProvider) Astronomy(ctx context.Context, loc Location, when time.Time) (Astronomy, error) {
+	body, err := p.get(ctx, loc, "astronomy")
+	if err != nil {
+		return Astronomy{}, err
+	}
+	return astronomyFromBody(body, when), nil
+}
+
+// CurrentAndAstronomy satisfies CombinedProvider: OpenWeatherMap's
+// current-weather response already contains everything Astronomy derives
+// (sunrise/sunset/timezone), so fetch() can use this to spend a single
+// upstream call per cycle instead of two redundant ones for the same body.
+func (p *openWeatherMapProvider) CurrentAndAstronomy(ctx context.Context, loc Location, when time.Time) (Observation, Astronomy, error) {
+	body, err := p.get(ctx, loc, "current")
+	if err != nil {
+		return Observation{}, Astronomy{}, err
+	}
+	return observationFromBody(body), astronomyFromBody(body, when), nil
+}
+
+func observationFromBody(body map[string]any) Observation {
+	main, _ := body["main"].(map[string]any)
+	wind, _ := body["wind"].(map[string]any)
+	clouds, _ := body["clouds"].(map[string]any)
+
+	tempC := kelvinToCelsius(floatOrZero(main, "temp"))
+	feelslikeC := tempC
+	if feelsLike, ok := main["feels_like"].(float64); ok {
+		feelslikeC = kelvinToCelsius(feelsLike)
+	}
+
+	obs := Observation{
+		TempC:         tempC,
+		FeelslikeC:    feelslikeC,
+		PressureMb:    floatOrZero(main, "pressure"),
+		HumidityPct:   floatOrZero(main, "humidity"),
+		CloudCoverPct: floatOrZero(clouds, "all"),
+		Condition:     conditionText(body),
+		ConditionCode: conditionCode(body),
+	}
+	if vis, ok := body["visibility"].(float64); ok {
+		obs.VisKm = vis / 1000
+	}
+	if wind != nil {
+		obs.WindKph = floatOrZero(wind, "speed") * 3.6
+		obs.GustKph = floatOrZero(wind, "gust") * 3.6
+		obs.WindDegree = floatOrZero(wind, "deg")
+	}
+	if rain, ok := body["rain"].(map[string]any); ok {
+		obs.PrecipMm = floatOrZero(rain, "1h")
+	} else if snow, ok := body["snow"].(map[string]any); ok {
+		obs.PrecipMm = floatOrZero(snow, "1h")
+	}
+	// OpenWeatherMap's current-weather endpoint has no dewpoint or UV index;
+	// those require the separate One Call API, so they're left at zero.
+	return obs
+}
+
+func astronomyFromBody(body map[string]any, when time.Time) Astronomy {
+	sys, _ := body["sys"].(map[string]any)
+	tzOffset := time.Duration(floatOrZero(body, "timezone")) * time.Second
+	tz := time.FixedZone("", int(tzOffset.Seconds()))
+
+	sunriseTS := int64(floatOrZero(sys, "sunrise"))
+	sunsetTS := int64(floatOrZero(sys, "sunset"))
+	// Keep these as the real UTC instants for the IsSunUp comparison against
+	// when; only shift into tz below when formatting for display, since Add
+	// would move the instant itself rather than just how it's displayed.
+	sunrise := time.Unix(sunriseTS, 0).UTC()
+	sunset := time.Unix(sunsetTS, 0).UTC()
+
+	return Astronomy{
+		IsSunUp: when.After(sunrise) && when.Before(sunset),
+		Sunrise: sunrise.In(tz).Format("03:04 PM"),
+		Sunset:  sunset.In(tz).Format("03:04 PM"),
+		// OpenWeatherMap's current-weather endpoint has no moon data.
+	}
+}
+
+func (p *openWeatherMapProvider) get(ctx context.Context, loc Location, endpoint string) (map[string]any, error) {
+	reqURL := fmt.Sprintf("%s?q=%s&appid=%s", openWeatherMapCurrentURL, url.QueryEscape(loc.Query), p.appID)
+	client := &http.Client{Timeout: 10 * time.Second}
+	req, err := http.NewRequestWithContext(ctx, "GET", reqURL, nil)
+	if err != nil {
+		p.logger.Errorf("Error creating request: %v\n", err)
+		return nil, err
+	}
+	req.Header.Add("Accept", "application/json")
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		p.logger.Errorf("Error making request: %v\n", err)
+		return nil, err
+	}
+	defer resp.Body.Close()
+	observeAPICall(endpoint, resp.StatusCode, time.Since(start))
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		p.logger.Errorf("Error reading response body: %v\n", err)
+		return nil, err
+	}
+	var responseJSON map[string]any
+	if err := json.Unmarshal(respBody, &responseJSON); err != nil {
+		p.logger.Errorf("Error unmarshaling to map: %v\n", err)
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		p.logger.Errorf("Unexpected status code: %d\n", resp.StatusCode)
+		message, ok := responseJSON["message"]
+		if !ok {
+			return nil, errors.Errorf("request failed with code %d, and no message in response body", resp.StatusCode)
+		}
+		return responseJSON, errors.Errorf("error fetching weather info, code: %d, message: %v", resp.StatusCode, message)
+	}
+	return responseJSON, nil
+}
+
+func kelvinToCelsius(k float64) float64 {
+	return k - 273.15
+}
+
+func floatOrZero(m map[string]any, key string) float64 {
+	if m == nil {
+		return 0
+	}
+	v, _ := m[key].(float64)
+	return v
+}
+
+func stringOrEmpty(m map[string]any, key string) string {
+	if m == nil {
+		return ""
+	}
+	v, _ := m[key].(string)
+	return v
+}
+
+func conditionText(body map[string]any) string {
+	weather, ok := body["weather"].([]any)
+	if !ok {
+		return ""
+	}
+	descriptions := make([]string, 0, len(weather))
+	for _, w := range weather {
+		wm, ok := w.(map[string]any)
+		if !ok {
+			continue
+		}
+		if desc, ok := wm["description"].(string); ok {
+			descriptions = append(descriptions, desc)
+		}
+	}
+	return strings.Join(descriptions, "; ")
+}
+
+func conditionCode(body map[string]any) int {
+	weather, ok := body["weather"].([]any)
+	if !ok || len(weather) == 0 {
+		return 0
+	}
+	wm, ok := weather[0].(map[string]any)
+	if !ok {
+		return 0
+	}
+	id, _ := wm["id"].(float64)
+	return int(id)
+}
@@ -0,0 +1,78 @@
+package models
+
+import (
+	"context"
+	"time"
+)
+
+// Location identifies where a Provider should fetch weather for. Query is
+// passed through to the provider largely as-is, and accepts whatever forms
+// the provider supports (zip code, "lat,lon", city name, IATA code).
+type Location struct {
+	Query string
+}
+
+// Observation is a provider-agnostic snapshot of current conditions. All
+// fields are stored in metric units (Celsius, kph, mb, mm, km); Readings
+// converts to the configured Units when building its output map.
+type Observation struct {
+	TempC         float64
+	FeelslikeC    float64
+	DewpointC     float64
+	WindKph       float64
+	GustKph       float64
+	WindDegree    float64
+	WindDir       string
+	PressureMb    float64
+	PrecipMm      float64
+	HumidityPct   float64
+	CloudCoverPct float64
+	UVIndex       float64
+	VisKm         float64
+	Condition     string
+	ConditionCode int
+	// AirQuality is passed through as-is when a provider supports it, and
+	// nil otherwise.
+	AirQuality map[string]any
+}
+
+// Astronomy is a provider-agnostic snapshot of sun/moon data. Providers that
+// don't expose a field (e.g. OpenWeatherMap has no moon data in its current
+// weather endpoint) leave it at its zero value.
+type Astronomy struct {
+	IsSunUp          bool
+	Sunrise          string
+	Sunset           string
+	Moonrise         string
+	Moonset          string
+	MoonPhase        string
+	MoonIllumination string
+}
+
+// Provider fetches weather data from a specific upstream vendor.
+type Provider interface {
+	CurrentWeather(ctx context.Context, loc Location) (Observation, error)
+	Astronomy(ctx context.Context, loc Location, when time.Time) (Astronomy, error)
+}
+
+// CombinedProvider is implemented by providers whose CurrentWeather and
+// Astronomy data come from the same upstream response (e.g. OpenWeatherMap's
+// current-weather endpoint contains both). fetch type-asserts against this
+// so those providers spend a single upstream call per cycle instead of two
+// redundant ones for the same payload.
+type CombinedProvider interface {
+	Provider
+	CurrentAndAstronomy(ctx context.Context, loc Location, when time.Time) (Observation, Astronomy, error)
+}
+
+// ExtendedProvider is implemented by providers that expose ad-hoc query
+// endpoints beyond CurrentWeather/Astronomy. DoCommand type-asserts against
+// this to serve forecast/history/alerts/search without growing the base
+// Provider interface that every backend must implement.
+type ExtendedProvider interface {
+	Provider
+	Forecast(ctx context.Context, loc Location, days int) (map[string]any, error)
+	History(ctx context.Context, loc Location, date string) (map[string]any, error)
+	Alerts(ctx context.Context, loc Location) (map[string]any, error)
+	Search(ctx context.Context, query string) (map[string]any, error)
+}
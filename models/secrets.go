@@ -0,0 +1,34 @@
+package models
+
+import (
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// resolveSecret resolves a config value that may itself be a literal secret,
+// a "$ENV_VAR" reference, or (when raw is empty) a path to a file holding
+// the secret. This lets operators inject secrets through the process
+// environment or a mounted file instead of checking them into robot config.
+func resolveSecret(raw, filePath string) (string, error) {
+	if strings.HasPrefix(raw, "$") {
+		envVar := strings.TrimPrefix(raw, "$")
+		val, ok := os.LookupEnv(envVar)
+		if !ok || val == "" {
+			return "", errors.Errorf("environment variable %q referenced by config is not set", envVar)
+		}
+		return val, nil
+	}
+	if raw != "" {
+		return raw, nil
+	}
+	if filePath != "" {
+		data, err := os.ReadFile(filePath)
+		if err != nil {
+			return "", errors.Wrapf(err, "reading secret file %q", filePath)
+		}
+		return strings.TrimSpace(string(data)), nil
+	}
+	return "", nil
+}
@@ -0,0 +1,75 @@
+package models
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveSecret(t *testing.T) {
+	t.Run("env var reference", func(t *testing.T) {
+		t.Setenv("WEATHERSENSOR_TEST_APIKEY", "env-value")
+		got, err := resolveSecret("$WEATHERSENSOR_TEST_APIKEY", "")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != "env-value" {
+			t.Errorf("resolveSecret = %q, want %q", got, "env-value")
+		}
+	})
+
+	t.Run("env var reference unset", func(t *testing.T) {
+		os.Unsetenv("WEATHERSENSOR_TEST_UNSET")
+		_, err := resolveSecret("$WEATHERSENSOR_TEST_UNSET", "")
+		if err == nil {
+			t.Fatal("expected error for unset environment variable, got nil")
+		}
+	})
+
+	t.Run("literal value takes precedence over file", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "secret")
+		if err := os.WriteFile(path, []byte("file-value"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		got, err := resolveSecret("literal-value", path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != "literal-value" {
+			t.Errorf("resolveSecret = %q, want %q", got, "literal-value")
+		}
+	})
+
+	t.Run("file fallback when raw is empty", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "secret")
+		if err := os.WriteFile(path, []byte("file-value\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		got, err := resolveSecret("", path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != "file-value" {
+			t.Errorf("resolveSecret = %q, want %q", got, "file-value")
+		}
+	})
+
+	t.Run("no raw and no file returns empty", func(t *testing.T) {
+		got, err := resolveSecret("", "")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != "" {
+			t.Errorf("resolveSecret = %q, want empty string", got)
+		}
+	})
+
+	t.Run("unreadable file returns error", func(t *testing.T) {
+		_, err := resolveSecret("", filepath.Join(t.TempDir(), "does-not-exist"))
+		if err == nil {
+			t.Fatal("expected error for missing secret file, got nil")
+		}
+	})
+}
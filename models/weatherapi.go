@@ -0,0 +1,251 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/logging"
+)
+
+// api ref: https://app.swaggerhub.com/apis-docs/WeatherAPI.com/WeatherAPI/1.0.2#/APIs/realtime-weather
+
+const (
+	weatherAPICurrentURL   = "https://api.weatherapi.com/v1/current.json"
+	weatherAPIAstronomyURL = "https://api.weatherapi.com/v1/astronomy.json"
+	weatherAPIForecastURL  = "https://api.weatherapi.com/v1/forecast.json"
+	weatherAPIHistoryURL   = "https://api.weatherapi.com/v1/history.json"
+	weatherAPIAlertsURL    = "https://api.weatherapi.com/v1/alerts.json"
+	weatherAPISearchURL    = "https://api.weatherapi.com/v1/search.json"
+)
+
+// weatherAPIProvider implements Provider against weatherapi.com, the
+// original (and still default) backend for this module.
+type weatherAPIProvider struct {
+	apiKey string
+	logger logging.Logger
+}
+
+func newWeatherAPIProvider(apiKey string, logger logging.Logger) *weatherAPIProvider {
+	return &weatherAPIProvider{apiKey: apiKey, logger: logger}
+}
+
+func (p *weatherAPIProvider) CurrentWeather(ctx context.Context, loc Location) (Observation, error) {
+	reqURL := fmt.Sprintf("%s?q=%s&aqi=yes&key=%s", weatherAPICurrentURL, url.QueryEscape(loc.Query), p.apiKey)
+	body, err := p.getWeatherBodyOrCode(ctx, "current", reqURL)
+	if err != nil {
+		return Observation{}, err
+	}
+	current, _ := body["current"].(map[string]any)
+	condition, _ := current["condition"].(map[string]any)
+
+	obs := Observation{
+		TempC:         floatOrZero(current, "temp_c"),
+		FeelslikeC:    floatOrZero(current, "feelslike_c"),
+		DewpointC:     floatOrZero(current, "dewpoint_c"),
+		WindKph:       floatOrZero(current, "wind_kph"),
+		GustKph:       floatOrZero(current, "gust_kph"),
+		WindDegree:    floatOrZero(current, "wind_degree"),
+		WindDir:       stringOrEmpty(current, "wind_dir"),
+		PressureMb:    floatOrZero(current, "pressure_mb"),
+		PrecipMm:      floatOrZero(current, "precip_mm"),
+		HumidityPct:   floatOrZero(current, "humidity"),
+		CloudCoverPct: floatOrZero(current, "cloud"),
+		UVIndex:       floatOrZero(current, "uv"),
+		VisKm:         floatOrZero(current, "vis_km"),
+		Condition:     stringOrEmpty(condition, "text"),
+		ConditionCode: int(floatOrZero(condition, "code")),
+	}
+	if airQuality, ok := current["air_quality"].(map[string]any); ok {
+		obs.AirQuality = airQuality
+	}
+	return obs, nil
+}
+
+func (p *weatherAPIProvider) Astronomy(ctx context.Context, loc Location, when time.Time) (Astronomy, error) {
+	reqURL := fmt.Sprintf("%s?q=%s&dt=%s&key=%s", weatherAPIAstronomyURL, url.QueryEscape(loc.Query), when.Format("2006-01-02"), p.apiKey)
+	body, err := p.getWeatherBodyOrCode(ctx, "astronomy", reqURL)
+	if err != nil {
+		return Astronomy{}, err
+	}
+	astronomy, _ := body["astronomy"].(map[string]any)
+	astro, _ := astronomy["astro"].(map[string]any)
+	isSunUp, _ := astro["is_sun_up"].(float64)
+	return Astronomy{
+		IsSunUp:          isSunUp != 0,
+		Sunrise:          stringOrEmpty(astro, "sunrise"),
+		Sunset:           stringOrEmpty(astro, "sunset"),
+		Moonrise:         stringOrEmpty(astro, "moonrise"),
+		Moonset:          stringOrEmpty(astro, "moonset"),
+		MoonPhase:        stringOrEmpty(astro, "moon_phase"),
+		MoonIllumination: fmt.Sprintf("%v", astro["moon_illumination"]),
+	}, nil
+}
+
+// Forecast, History, Alerts, and Search serve the DoCommand queries that
+// don't belong in the small, periodic CurrentWeather/Astronomy path. They
+// return the raw WeatherAPI response so callers can script arbitrary
+// queries through DoCommand without the module needing to model every field.
+
+func (p *weatherAPIProvider) Forecast(ctx context.Context, loc Location, days int) (map[string]any, error) {
+	reqURL := fmt.Sprintf("%s?q=%s&days=%d&key=%s", weatherAPIForecastURL, url.QueryEscape(loc.Query), days, p.apiKey)
+	return p.getWeatherBodyOrCode(ctx, "forecast", reqURL)
+}
+
+func (p *weatherAPIProvider) History(ctx context.Context, loc Location, date string) (map[string]any, error) {
+	reqURL := fmt.Sprintf("%s?q=%s&dt=%s&key=%s", weatherAPIHistoryURL, url.QueryEscape(loc.Query), url.QueryEscape(date), p.apiKey)
+	return p.getWeatherBodyOrCode(ctx, "history", reqURL)
+}
+
+func (p *weatherAPIProvider) Alerts(ctx context.Context, loc Location) (map[string]any, error) {
+	reqURL := fmt.Sprintf("%s?q=%s&key=%s", weatherAPIAlertsURL, url.QueryEscape(loc.Query), p.apiKey)
+	return p.getWeatherBodyOrCode(ctx, "alerts", reqURL)
+}
+
+// Search doesn't reuse getWeatherBodyOrCode because search.json responds
+// with a top-level JSON array on success rather than an object.
+func (p *weatherAPIProvider) Search(ctx context.Context, query string) (map[string]any, error) {
+	reqURL := fmt.Sprintf("%s?q=%s&key=%s", weatherAPISearchURL, url.QueryEscape(query), p.apiKey)
+	client := &http.Client{Timeout: 10 * time.Second}
+	req, err := http.NewRequestWithContext(ctx, "GET", reqURL, nil)
+	if err != nil {
+		p.logger.Errorf("Error creating request: %v\n", err)
+		return nil, err
+	}
+	req.Header.Add("Accept", "application/json")
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		p.logger.Errorf("Error making request: %v\n", err)
+		return nil, err
+	}
+	defer resp.Body.Close()
+	observeAPICall("search", resp.StatusCode, time.Since(start))
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		p.logger.Errorf("Error reading response body: %v\n", err)
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("error searching locations, code: %d, body: %s", resp.StatusCode, body)
+	}
+	var results []any
+	if err := json.Unmarshal(body, &results); err != nil {
+		p.logger.Errorf("Error unmarshaling search results: %v\n", err)
+		return nil, err
+	}
+	return map[string]any{"results": results}, nil
+}
+
+// maxRateLimitRetries bounds how many times getWeatherBodyOrCode will retry
+// a 429 before giving up, so a persistently exhausted quota still surfaces
+// as an error instead of blocking Readings forever.
+const maxRateLimitRetries = 5
+
+const (
+	initialRateLimitBackoff = 1 * time.Second
+	maxRateLimitBackoff     = 30 * time.Second
+)
+
+func (p *weatherAPIProvider) getWeatherBodyOrCode(ctx context.Context, endpoint, reqURL string) (map[string]any, error) {
+	backoff := initialRateLimitBackoff
+	for attempt := 0; ; attempt++ {
+		responseJSON, resp, err := p.doRequest(ctx, endpoint, reqURL)
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode == http.StatusTooManyRequests && attempt < maxRateLimitRetries {
+			wait := retryAfter(resp.Header.Get("Retry-After"), backoff)
+			p.logger.Warnf("Rate limited by weatherapi.com, retrying in %s\n", wait)
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(wait):
+			}
+			backoff *= 2
+			if backoff > maxRateLimitBackoff {
+				backoff = maxRateLimitBackoff
+			}
+			continue
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			p.logger.Errorf("Unexpected status code: %d\n", resp.StatusCode)
+			code, ok := responseJSON["code"]
+			if !ok {
+				return nil, errors.Errorf("request failed with code %d, and no code in response body", resp.StatusCode)
+			}
+			message, messageOK := responseJSON["message"]
+			if !messageOK {
+				return nil, errors.Errorf("request failed with code %d, and no message in response body", resp.StatusCode)
+			}
+			return responseJSON, errors.Errorf("error fetching weather info, code: %d, message: %s", code, message)
+		}
+		return responseJSON, nil
+	}
+}
+
+// doRequest performs a single HTTP round trip and decodes the JSON body,
+// returning the raw *http.Response alongside it so callers can inspect the
+// status code and headers (e.g. Retry-After) before deciding whether to
+// retry.
+func (p *weatherAPIProvider) doRequest(ctx context.Context, endpoint, reqURL string) (map[string]any, *http.Response, error) {
+	client := &http.Client{
+		Timeout: 10 * time.Second,
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", reqURL, nil)
+	if err != nil {
+		p.logger.Errorf("Error creating request: %v\n", err)
+		return nil, nil, err
+	}
+	req.Header.Add("Accept", "application/json")
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		p.logger.Errorf("Error making request: %v\n", err)
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	observeAPICall(endpoint, resp.StatusCode, time.Since(start))
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		p.logger.Errorf("Error reading response body: %v\n", err)
+		return nil, nil, err
+	}
+	var responseJSON map[string]interface{}
+	if err := json.Unmarshal(body, &responseJSON); err != nil {
+		p.logger.Errorf("Error unmarshaling to map: %v\n", err)
+		return nil, nil, err
+	}
+	return responseJSON, resp, nil
+}
+
+// retryAfter parses a Retry-After header (seconds or an HTTP-date), falling
+// back to the given backoff duration when the header is absent or
+// unparseable.
+func retryAfter(header string, backoff time.Duration) time.Duration {
+	if header == "" {
+		return backoff
+	}
+	if seconds, err := strconv.Atoi(header); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if when, err := http.ParseTime(header); err == nil {
+		if wait := time.Until(when); wait > 0 {
+			return wait
+		}
+	}
+	return backoff
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRetryAfter(t *testing.T) {
+	const fallback = 5 * time.Second
+
+	t.Run("empty header falls back", func(t *testing.T) {
+		if got := retryAfter("", fallback); got != fallback {
+			t.Errorf("retryAfter(\"\", %v) = %v, want %v", fallback, got, fallback)
+		}
+	})
+
+	t.Run("seconds", func(t *testing.T) {
+		got := retryAfter("30", fallback)
+		want := 30 * time.Second
+		if got != want {
+			t.Errorf("retryAfter(\"30\", %v) = %v, want %v", fallback, got, want)
+		}
+	})
+
+	t.Run("HTTP-date in the future", func(t *testing.T) {
+		when := time.Now().Add(2 * time.Minute)
+		header := when.UTC().Format(http.TimeFormat)
+		got := retryAfter(header, fallback)
+		if got <= 0 || got > 2*time.Minute {
+			t.Errorf("retryAfter(%q, %v) = %v, want a positive duration close to 2m", header, fallback, got)
+		}
+	})
+
+	t.Run("HTTP-date in the past falls back", func(t *testing.T) {
+		when := time.Now().Add(-2 * time.Minute)
+		header := when.UTC().Format(http.TimeFormat)
+		if got := retryAfter(header, fallback); got != fallback {
+			t.Errorf("retryAfter(%q, %v) = %v, want %v", header, fallback, got, fallback)
+		}
+	})
+
+	t.Run("unparseable header falls back", func(t *testing.T) {
+		if got := retryAfter("not-a-valid-value", fallback); got != fallback {
+			t.Errorf("retryAfter(\"not-a-valid-value\", %v) = %v, want %v", fallback, got, fallback)
+		}
+	})
+}